@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/urfave/cli"
+)
+
+var batchIssueCommand = cli.Command{
+	Name:      "batch-issue",
+	Usage:     "stream-issue many grants for a single promotion from a CSV of probi[,altcurrency] rows",
+	ArgsUsage: "<csv file>",
+	Flags: []cli.Flag{
+		cli.StringFlag{Name: "promotion-id", Usage: "uuid of the promotion every grant in the batch belongs to", Required: true},
+		cli.StringFlag{Name: "key", Usage: "path to the ed25519 signing private key (hex or PEM)", Required: true},
+		cli.BoolFlag{Name: "encrypted-key", Usage: "the key file is encrypted at rest; prompt for a passphrase to unlock it"},
+	},
+	Action: func(c *cli.Context) error {
+		if c.NArg() != 1 {
+			return errors.New("batch-issue takes exactly one argument, the path to a CSV file")
+		}
+		key, err := loadSigningKey(c.String("key"), c.Bool("encrypted-key"))
+		if err != nil {
+			return err
+		}
+
+		f, err := os.Open(c.Args().Get(0))
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		w := bufio.NewWriter(os.Stdout)
+		defer w.Flush()
+
+		r := csv.NewReader(f)
+		r.FieldsPerRecord = -1 // altcurrency column is optional
+		issued := 0
+		for {
+			row, err := r.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			if len(row) == 0 {
+				continue
+			}
+
+			probi := row[0]
+			altcurrencyName := "BAT"
+			if len(row) > 1 && row[1] != "" {
+				altcurrencyName = row[1]
+			}
+
+			g, err := buildGrant(c.String("promotion-id"), probi, altcurrencyName)
+			if err != nil {
+				return fmt.Errorf("row %d: %w", issued+1, err)
+			}
+			compact, err := signGrant(key, g)
+			if err != nil {
+				return fmt.Errorf("row %d: %w", issued+1, err)
+			}
+			if _, err := fmt.Fprintln(w, compact); err != nil {
+				return err
+			}
+			issued++
+		}
+
+		fmt.Fprintf(os.Stderr, "issued %d grants for promotion %s\n", issued, c.String("promotion-id"))
+		return nil
+	},
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/brave-intl/bat-go/grant"
+	"github.com/brave-intl/bat-go/utils/altcurrency"
+	"github.com/satori/go.uuid"
+	"github.com/shopspring/decimal"
+	"github.com/square/go-jose"
+	"github.com/urfave/cli"
+	"golang.org/x/crypto/ed25519"
+)
+
+var issueCommand = cli.Command{
+	Name:  "issue",
+	Usage: "issue a single signed grant",
+	Flags: []cli.Flag{
+		cli.StringFlag{Name: "promotion-id", Usage: "uuid of the promotion this grant belongs to", Required: true},
+		cli.StringFlag{Name: "probi", Usage: "grant amount, in probi", Required: true},
+		cli.StringFlag{Name: "altcurrency", Usage: "altcurrency the grant is denominated in", Value: "BAT"},
+		cli.StringFlag{Name: "key", Usage: "path to the ed25519 signing private key (hex or PEM)", Required: true},
+		cli.BoolFlag{Name: "encrypted-key", Usage: "the key file is encrypted at rest; prompt for a passphrase to unlock it"},
+	},
+	Action: func(c *cli.Context) error {
+		key, err := loadSigningKey(c.String("key"), c.Bool("encrypted-key"))
+		if err != nil {
+			return err
+		}
+		g, err := buildGrant(c.String("promotion-id"), c.String("probi"), c.String("altcurrency"))
+		if err != nil {
+			return err
+		}
+		compact, err := signGrant(key, g)
+		if err != nil {
+			return err
+		}
+		fmt.Println(compact)
+		return nil
+	},
+}
+
+// buildGrant constructs a fresh grant.Grant with a new random GrantId from the given
+// promotion, probi, and altcurrency flag values.
+func buildGrant(promotionID, probi, altcurrencyName string) (*grant.Grant, error) {
+	promotionUUID, err := uuid.FromString(promotionID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid promotion-id: %w", err)
+	}
+	amount, err := decimal.NewFromString(probi)
+	if err != nil {
+		return nil, fmt.Errorf("invalid probi: %w", err)
+	}
+	return &grant.Grant{
+		AltCurrency: altcurrency.AltCurrency(strings.ToUpper(altcurrencyName)),
+		GrantId:     uuid.NewV4(),
+		Probi:       amount,
+		PromotionId: promotionUUID,
+	}, nil
+}
+
+// signGrant serializes g and signs it with key, returning a compact JWS suitable for
+// grant.FromCompactJWS.
+func signGrant(key ed25519.PrivateKey, g *grant.Grant) (string, error) {
+	b, err := json.Marshal(g)
+	if err != nil {
+		return "", err
+	}
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: "ed25519", Key: key}, nil)
+	if err != nil {
+		return "", err
+	}
+	jws, err := signer.Sign(b)
+	if err != nil {
+		return "", err
+	}
+	return jws.CompactSerialize()
+}
@@ -0,0 +1,28 @@
+// Command bat-grant issues, inspects, and redeems the compact JWS grants that
+// grant.FromCompactJWS consumes, so promotions can be run without embedding signing
+// code in ad-hoc scripts.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/urfave/cli"
+)
+
+func main() {
+	app := cli.NewApp()
+	app.Name = "bat-grant"
+	app.Usage = "issue, inspect, and redeem BAT grants"
+	app.Commands = []cli.Command{
+		issueCommand,
+		batchIssueCommand,
+		verifyCommand,
+		redeemCommand,
+	}
+
+	if err := app.Run(os.Args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/brave-intl/bat-go/grant"
+	"github.com/brave-intl/bat-go/wallet"
+	"github.com/urfave/cli"
+)
+
+var redeemCommand = cli.Command{
+	Name:  "redeem",
+	Usage: "exercise grant redemption end-to-end, against a running server or a local datastore",
+	Flags: []cli.Flag{
+		cli.StringSliceFlag{Name: "grants", Usage: "compact JWS grant, repeatable", Required: true},
+		cli.StringFlag{Name: "wallet-info", Usage: "path to a JSON file containing the wallet.WalletInfo to redeem into", Required: true},
+		cli.StringFlag{Name: "transaction", Usage: "base64 settlement transaction blob", Required: true},
+		cli.StringFlag{Name: "server", Usage: "base URL of a running grant server; if unset, redeems against InitGrantService's local configuration instead"},
+	},
+	Action: func(c *cli.Context) error {
+		walletInfoBytes, err := ioutil.ReadFile(c.String("wallet-info"))
+		if err != nil {
+			return err
+		}
+		var walletInfo wallet.WalletInfo
+		if err := json.Unmarshal(walletInfoBytes, &walletInfo); err != nil {
+			return fmt.Errorf("invalid wallet-info: %w", err)
+		}
+
+		req := &grant.RedeemGrantsRequest{
+			Grants:      c.StringSlice("grants"),
+			WalletInfo:  walletInfo,
+			Transaction: c.String("transaction"),
+		}
+
+		if server := c.String("server"); server != "" {
+			return redeemAgainstServer(server, req)
+		}
+		return redeemLocally(req)
+	},
+}
+
+func redeemAgainstServer(server string, req *grant.RedeemGrantsRequest) error {
+	b, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	resp, err := http.Post(server+"/v1/grants", "application/json", bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("server returned %s: %s", resp.Status, body)
+	}
+	fmt.Println(string(body))
+	return nil
+}
+
+func redeemLocally(req *grant.RedeemGrantsRequest) error {
+	if err := grant.InitGrantService(); err != nil {
+		return errors.New("failed to initialize local grant service, and no --server was given: " + err.Error())
+	}
+	if err := req.Redeem(context.Background()); err != nil {
+		return err
+	}
+	fmt.Println("redeemed successfully")
+	return nil
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/brave-intl/bat-go/grant"
+	"github.com/square/go-jose"
+	"github.com/urfave/cli"
+)
+
+var verifyCommand = cli.Command{
+	Name:      "verify",
+	Usage:     "decode and validate a compact JWS grant against GRANT_SIGNATOR_PUBLIC_KEY",
+	ArgsUsage: "<jws>",
+	Action: func(c *cli.Context) error {
+		if c.NArg() != 1 {
+			return errors.New("verify takes exactly one argument, the compact JWS grant")
+		}
+
+		pubKeyHex := os.Getenv("GRANT_SIGNATOR_PUBLIC_KEY")
+		if pubKeyHex == "" {
+			return errors.New("GRANT_SIGNATOR_PUBLIC_KEY must be set")
+		}
+		pubKey, err := hex.DecodeString(pubKeyHex)
+		if err != nil {
+			return fmt.Errorf("invalid GRANT_SIGNATOR_PUBLIC_KEY: %w", err)
+		}
+
+		jws, err := jose.ParseSigned(c.Args().Get(0))
+		if err != nil {
+			return err
+		}
+		for _, sig := range jws.Signatures {
+			if sig.Header.Algorithm != "ed25519" {
+				return errors.New("unsupported JWS algorithm")
+			}
+		}
+		grantBytes, err := jws.Verify(jose.JSONWebKey{Key: pubKey})
+		if err != nil {
+			return fmt.Errorf("signature verification failed: %w", err)
+		}
+
+		var g grant.Grant
+		if err := json.Unmarshal(grantBytes, &g); err != nil {
+			return err
+		}
+		out, err := json.MarshalIndent(g, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+		return nil
+	},
+}
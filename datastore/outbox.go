@@ -0,0 +1,236 @@
+package datastore
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"time"
+
+	"github.com/brave-intl/bat-go/wallet"
+	"github.com/garyburd/redigo/redis"
+)
+
+// OutboxState represents the lifecycle stage of a durable redemption record.
+type OutboxState string
+
+const (
+	// OutboxStatePending means the record has been written but no external side effect
+	// (transfer or settlement submission) has been confirmed to succeed yet; an attempt
+	// that failed with a transient error leaves the entry pending.
+	OutboxStatePending OutboxState = "pending"
+	// OutboxStateFunded means the grant wallet transfer to the user has succeeded and
+	// only the settlement submission remains.
+	OutboxStateFunded OutboxState = "funded"
+	// OutboxStateSettled is the terminal success state; both legs completed.
+	OutboxStateSettled OutboxState = "settled"
+	// OutboxStateFailed is the terminal failure state; this entry will not be retried
+	// by the resume worker.
+	OutboxStateFailed OutboxState = "failed"
+)
+
+// ErrOutboxEntryNotFound is returned by OutboxDatastore.Get when requestHash is unknown.
+var ErrOutboxEntryNotFound = errors.New("outbox entry not found")
+
+// OutboxEntry is a durable record of a single grant redemption, written before any
+// external side effect so that a crash between steps can be resumed deterministically
+// from the last known state rather than re-run from scratch.
+type OutboxEntry struct {
+	RequestHash     string                  `json:"requestHash"`
+	State           OutboxState             `json:"state"`
+	GrantIds        []string                `json:"grantIds"`
+	WalletInfo      wallet.WalletInfo       `json:"walletInfo"`
+	Transaction     string                  `json:"transaction"`
+	TransactionInfo *wallet.TransactionInfo `json:"transactionInfo,omitempty"`
+	TransferRef     string                  `json:"transferRef,omitempty"`
+	FailureReason   string                  `json:"failureReason,omitempty"`
+	CreatedAt       time.Time               `json:"createdAt"`
+	UpdatedAt       time.Time               `json:"updatedAt"`
+
+	// The Eth* fields below are populated only for entries written by
+	// grant.RedeemEthGrant (keyed "eth:<grantId>"), carrying everything needed to finish
+	// driving an interrupted on-chain redemption without the original caller around to
+	// retry it. WalletInfo/Transaction/TransactionInfo are left zero for these entries;
+	// the off-chain path has no equivalent use for the fields below.
+	EthSettlementContract string `json:"ethSettlementContract,omitempty"`
+	EthSecretHash         string `json:"ethSecretHash,omitempty"`
+	EthInitTxHash         string `json:"ethInitTxHash,omitempty"`
+	EthRecipient          string `json:"ethRecipient,omitempty"`
+	// EthSecret is the HTLC preimage: sensitive in the same way a signed settlement
+	// transaction is, and persisted for the same reason - without it a crash between
+	// claiming the redeemedGrants guard and confirming redeem() on-chain could never be
+	// resumed.
+	EthSecret string `json:"ethSecret,omitempty"`
+}
+
+// IsTerminal returns true if the entry is in a state that the resume worker should
+// no longer act on.
+func (e *OutboxEntry) IsTerminal() bool {
+	return e.State == OutboxStateSettled || e.State == OutboxStateFailed
+}
+
+// OutboxDatastore persists redemption state keyed by a deterministic request hash so
+// that Redeem can resume after a crash instead of re-running external side effects,
+// and so that Verify can recognize a retried request instead of double-consuming grants.
+type OutboxDatastore interface {
+	// Get returns the outbox entry for requestHash, or ErrOutboxEntryNotFound if none exists.
+	Get(requestHash string) (*OutboxEntry, error)
+	// Put creates or overwrites the outbox entry for entry.RequestHash.
+	Put(entry *OutboxEntry) error
+	// ListNonTerminal returns every entry not yet in a terminal state, for the resume
+	// worker to pick back up on startup.
+	ListNonTerminal() ([]OutboxEntry, error)
+	// AcquireLock attempts to take a short-lived exclusive lock on requestHash, so that
+	// two concurrent callers processing the identical (retried) request serialize rather
+	// than both racing through expensive, side-effecting verification logic and writing
+	// the outbox entry out from under one another. acquired is false if another caller
+	// already holds the lock. release must be called exactly once if the lock was
+	// acquired; when it was not, release is a no-op.
+	AcquireLock(requestHash string) (acquired bool, release func() error, err error)
+	Close() error
+}
+
+// AwaitEntry polls outbox for requestHash until it appears or ctx is done. A caller that
+// lost the AcquireLock race for requestHash uses this to wait for the winner to finish
+// writing the entry, rather than re-running the work itself.
+func AwaitEntry(ctx context.Context, outbox OutboxDatastore, requestHash string) (*OutboxEntry, error) {
+	const pollInterval = 100 * time.Millisecond
+	for {
+		entry, err := outbox.Get(requestHash)
+		if err == nil {
+			return entry, nil
+		} else if err != ErrOutboxEntryNotFound {
+			return nil, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// outboxKeyPrefix namespaces outbox keys in the shared redis keyspace.
+const outboxKeyPrefix = "outbox:"
+
+// outboxIndexKey is a redis set of every request hash that has ever been written,
+// letting ListNonTerminal avoid a full keyspace scan.
+const outboxIndexKey = "outbox-index"
+
+// outboxLockPrefix namespaces the short-lived locks AcquireLock takes, separately from
+// the outbox entries themselves so a lock's TTL expiring can never be confused with the
+// entry it guards disappearing.
+const outboxLockPrefix = "outbox-lock:"
+
+// outboxLockTTL bounds how long a lock can be held, so a caller that crashes after
+// acquiring one does not wedge every future retry of the same request forever.
+const outboxLockTTL = 30 * time.Second
+
+// releaseLockScript deletes a lock only if it still holds the token that created it, so
+// releasing a lock this caller held that has since expired and been re-acquired by
+// someone else cannot delete the new owner's lock out from under them.
+var releaseLockScript = redis.NewScript(1, `
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+else
+	return 0
+end
+`)
+
+type redisOutboxDatastore struct {
+	conn redis.Conn
+}
+
+// GetOutboxDatastore returns a connection to the shared transaction outbox, following
+// the same REDIS_URL convention as GetSetDatastore.
+func GetOutboxDatastore(ctx context.Context) (OutboxDatastore, error) {
+	conn, err := redis.DialURL(os.Getenv("REDIS_URL"))
+	if err != nil {
+		return nil, err
+	}
+	return &redisOutboxDatastore{conn: conn}, nil
+}
+
+func (d *redisOutboxDatastore) Get(requestHash string) (*OutboxEntry, error) {
+	b, err := redis.Bytes(d.conn.Do("GET", outboxKeyPrefix+requestHash))
+	if err == redis.ErrNil {
+		return nil, ErrOutboxEntryNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	var entry OutboxEntry
+	if err := json.Unmarshal(b, &entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (d *redisOutboxDatastore) Put(entry *OutboxEntry) error {
+	now := time.Now()
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = now
+	}
+	entry.UpdatedAt = now
+
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if _, err := d.conn.Do("SET", outboxKeyPrefix+entry.RequestHash, b); err != nil {
+		return err
+	}
+	_, err = d.conn.Do("SADD", outboxIndexKey, entry.RequestHash)
+	return err
+}
+
+func (d *redisOutboxDatastore) ListNonTerminal() ([]OutboxEntry, error) {
+	hashes, err := redis.Strings(d.conn.Do("SMEMBERS", outboxIndexKey))
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]OutboxEntry, 0, len(hashes))
+	for _, hash := range hashes {
+		entry, err := d.Get(hash)
+		if err == ErrOutboxEntryNotFound {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		if !entry.IsTerminal() {
+			entries = append(entries, *entry)
+		}
+	}
+	return entries, nil
+}
+
+func (d *redisOutboxDatastore) AcquireLock(requestHash string) (bool, func() error, error) {
+	noop := func() error { return nil }
+
+	tokenBytes := make([]byte, 16)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return false, noop, err
+	}
+	token := hex.EncodeToString(tokenBytes)
+	key := outboxLockPrefix + requestHash
+
+	reply, err := d.conn.Do("SET", key, token, "NX", "PX", outboxLockTTL.Milliseconds())
+	if err != nil {
+		return false, noop, err
+	}
+	if reply == nil {
+		// SET ... NX returned nil: someone else already holds the lock.
+		return false, noop, nil
+	}
+
+	release := func() error {
+		_, err := releaseLockScript.Do(d.conn, key, token)
+		return err
+	}
+	return true, release, nil
+}
+
+func (d *redisOutboxDatastore) Close() error {
+	return d.conn.Close()
+}
@@ -0,0 +1,225 @@
+package grant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/brave-intl/bat-go/datastore"
+	"github.com/brave-intl/bat-go/wallet/provider/ethswap"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pressly/lg"
+)
+
+var (
+	// EthNodeURL is the JSON-RPC endpoint used to audit and submit ETHSwap transactions.
+	EthNodeURL = os.Getenv("ETH_NODE_URL")
+	// GrantWalletEthPrivateKeyHex signs the redeem() transaction that releases an
+	// eth-settled grant's escrowed value to its recipient.
+	GrantWalletEthPrivateKeyHex = os.Getenv("GRANT_WALLET_ETH_PRIVATE_KEY")
+	// ethConfirmationsRequired is how many confirmations an initiate() transaction must
+	// have before its grant is considered settleable.
+	ethConfirmationsRequired uint64 = 12
+	// ethMinRemainingLocktime is the minimum time an initiate() transaction's locktime
+	// must still have left before its grant is considered safe to redeem. Without this
+	// margin, a too-short locktime could expire and become refundable before the
+	// recipient's redemption lands, silently defeating the HTLC guarantee the whole
+	// feature depends on.
+	ethMinRemainingLocktime = 1 * time.Hour
+)
+
+// VerifyEthGrant confirms that grant's on-chain settlement has been correctly
+// initiated: the ETHSwap initiate() transaction identified by initTxHash must pay
+// recipient, match the grant's probi and secretHash, be mined to ethConfirmationsRequired
+// confirmations, and leave at least ethMinRemainingLocktime before it can be refunded,
+// before the grant may be redeemed.
+func VerifyEthGrant(ctx context.Context, grant *Grant, initTxHash common.Hash, recipient common.Address) (*ethswap.Initiation, error) {
+	if grant.SettlementContract == nil || grant.SecretHash == nil {
+		return nil, errors.New("grant is not eth-settled")
+	}
+
+	client, err := ethswap.DialNodeClient(EthNodeURL)
+	if err != nil {
+		return nil, err
+	}
+	swap := ethswap.NewETHSwap(common.HexToAddress(*grant.SettlementContract), client)
+
+	init, confs, err := swap.AuditContract(ctx, initTxHash)
+	if err != nil {
+		return nil, err
+	}
+	if confs < ethConfirmationsRequired {
+		return nil, fmt.Errorf("ethswap: init tx only has %d confirmations, need %d", confs, ethConfirmationsRequired)
+	}
+	if init.Recipient != recipient {
+		return nil, errors.New("ethswap: init tx recipient does not match the redeeming wallet")
+	}
+	if init.SecretHash != common.HexToHash(*grant.SecretHash) {
+		return nil, errors.New("ethswap: init tx secretHash does not match grant")
+	}
+	if init.Value.Cmp(grant.Probi.BigInt()) != 0 {
+		return nil, errors.New("ethswap: init tx value does not match grant probi")
+	}
+	if remaining := time.Unix(init.Locktime.Int64(), 0).Sub(time.Now()); remaining < ethMinRemainingLocktime {
+		return nil, fmt.Errorf("ethswap: init tx locktime leaves only %s remaining, need at least %s", remaining, ethMinRemainingLocktime)
+	}
+	return init, nil
+}
+
+// RedeemEthGrant reveals secret on-chain, releasing the value escrowed for grant to
+// recipient, and records the redemption transaction hash in the outbox so a crash
+// between submission and confirmation can be recognized as already-redeemed on retry.
+func RedeemEthGrant(ctx context.Context, grant *Grant, initTxHash common.Hash, recipient common.Address, secret common.Hash) (common.Hash, error) {
+	requestHash := "eth:" + grant.GrantId.String()
+	outbox, err := datastore.GetOutboxDatastore(ctx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	defer outbox.Close()
+
+	if existing, err := outbox.Get(requestHash); err == nil {
+		return replayOrResumeEthRedemption(ctx, outbox, existing)
+	} else if err != datastore.ErrOutboxEntryNotFound {
+		return common.Hash{}, err
+	}
+
+	// The Get above and the outbox.Put below are not atomic with each other, so two
+	// concurrent retries of the identical eth redemption can both pass the "not found"
+	// check and both race through VerifyEthGrant and the redeemedGrants guard below.
+	// AcquireLock serializes them the same way chunk0-1's fix serialized
+	// RedeemGrantsRequest.Verify: only the winner runs that logic, and the loser waits
+	// for the winner's outbox entry and replays (or resumes) it instead of hitting the
+	// redeemedGrants guard as a hard "already redeemed" error.
+	acquired, release, err := outbox.AcquireLock(requestHash)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if !acquired {
+		entry, err := datastore.AwaitEntry(ctx, outbox, requestHash)
+		if err != nil {
+			return common.Hash{}, err
+		}
+		return replayOrResumeEthRedemption(ctx, outbox, entry)
+	}
+	defer release()
+
+	// The winner of the race above may still find an entry here: another caller could
+	// have acquired the lock, written the entry, and released it entirely between our
+	// first Get and acquiring the lock ourselves.
+	if existing, err := outbox.Get(requestHash); err == nil {
+		return replayOrResumeEthRedemption(ctx, outbox, existing)
+	} else if err != datastore.ErrOutboxEntryNotFound {
+		return common.Hash{}, err
+	}
+
+	init, err := VerifyEthGrant(ctx, grant, initTxHash, recipient)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	// Consult the same redeemedGrants/redeemedWallets guard RedeemGrantsRequest.Verify
+	// uses, so a grant cannot be drained once through this on-chain path and again
+	// through the off-chain one (or twice through this one by two different recipients).
+	redeemedGrants, err := datastore.GetSetDatastore(ctx, "promotion:"+grant.PromotionId.String()+":grants")
+	if err != nil {
+		return common.Hash{}, err
+	}
+	defer redeemedGrants.Close()
+	redeemedWallets, err := datastore.GetSetDatastore(ctx, "promotion:"+grant.PromotionId.String()+":wallets")
+	if err != nil {
+		return common.Hash{}, err
+	}
+	defer redeemedWallets.Close()
+
+	added, err := redeemedGrants.Add(grant.GrantId.String())
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if !added {
+		return common.Hash{}, fmt.Errorf("grant %s has already been redeemed", grant.GrantId)
+	}
+	added, err = redeemedWallets.Add(recipient.Hex())
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if !added {
+		return common.Hash{}, fmt.Errorf("wallet %s has already redeemed a grant from this promotion", recipient.Hex())
+	}
+
+	// Persist everything resumeEthRedemption needs to finish this redemption *before* the
+	// on-chain side effect is attempted, so a crash right after claiming the guard above
+	// leaves a genuinely resumable entry instead of a grant marked consumed with no way to
+	// ever redeem it - the same ordering ResumePendingRedemptions relies on for the
+	// off-chain path.
+	entry := &datastore.OutboxEntry{
+		RequestHash:           requestHash,
+		State:                 datastore.OutboxStatePending,
+		GrantIds:              []string{grant.GrantId.String()},
+		EthSettlementContract: *grant.SettlementContract,
+		EthSecretHash:         init.SecretHash.Hex(),
+		EthInitTxHash:         initTxHash.Hex(),
+		EthRecipient:          recipient.Hex(),
+		EthSecret:             secret.Hex(),
+	}
+	if err := outbox.Put(entry); err != nil {
+		return common.Hash{}, err
+	}
+
+	return resumeEthRedemption(ctx, outbox, entry)
+}
+
+// replayOrResumeEthRedemption returns a terminal entry's transfer reference, or drives a
+// still-pending one forward, instead of (as before) treating any existing entry at all
+// as a completed redemption - which, for a Pending entry with an empty TransferRef,
+// silently reported success for a redeem() that was never actually sent.
+func replayOrResumeEthRedemption(ctx context.Context, outbox datastore.OutboxDatastore, entry *datastore.OutboxEntry) (common.Hash, error) {
+	if entry.State == datastore.OutboxStateSettled {
+		return common.HexToHash(entry.TransferRef), nil
+	}
+	return resumeEthRedemption(ctx, outbox, entry)
+}
+
+// resumeEthRedemption drives a pending eth redemption entry to settled by (re)submitting
+// redeem() with the secret and secretHash recorded at Pending time. It is safe to call
+// repeatedly: a transient error (the likely kind - a dropped RPC, a gas estimation
+// hiccup, a nonce race) leaves the entry Pending rather than marking it permanently
+// failed, so both RedeemEthGrant retries and ResumePendingRedemptions keep resuming it
+// until it lands on-chain or the escrow's own locktime makes it moot.
+func resumeEthRedemption(ctx context.Context, outbox datastore.OutboxDatastore, entry *datastore.OutboxEntry) (common.Hash, error) {
+	log := lg.Log(ctx)
+
+	privKey, err := crypto.HexToECDSA(GrantWalletEthPrivateKeyHex)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	auth := bind.NewKeyedTransactor(privKey)
+
+	client, err := ethswap.DialNodeClient(EthNodeURL)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	swap := ethswap.NewETHSwap(common.HexToAddress(entry.EthSettlementContract), client)
+
+	secretHash := common.HexToHash(entry.EthSecretHash)
+	secret := common.HexToHash(entry.EthSecret)
+
+	tx, err := swap.Redeem(ctx, auth, secretHash, secret)
+	if err != nil {
+		entry.FailureReason = err.Error()
+		outbox.Put(entry)
+		return common.Hash{}, err
+	}
+
+	log.Infof("Submitted eth redemption for grant(s) %v: %s", entry.GrantIds, tx.Hash().Hex())
+
+	entry.State = datastore.OutboxStateSettled
+	entry.TransferRef = tx.Hash().Hex()
+	if err := outbox.Put(entry); err != nil {
+		return tx.Hash(), err
+	}
+	return tx.Hash(), nil
+}
@@ -2,6 +2,7 @@ package grant
 
 import (
 	"context"
+	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
@@ -10,7 +11,7 @@ import (
 	"github.com/brave-intl/bat-go/utils/altcurrency"
 	"github.com/brave-intl/bat-go/wallet"
 	"github.com/brave-intl/bat-go/wallet/provider"
-	"github.com/brave-intl/bat-go/wallet/provider/uphold"
+	_ "github.com/brave-intl/bat-go/wallet/provider/uphold" // registers the "uphold" provider
 	"github.com/pressly/lg"
 	"github.com/satori/go.uuid"
 	"github.com/shopspring/decimal"
@@ -18,35 +19,49 @@ import (
 	"golang.org/x/crypto/ed25519"
 	"os"
 	"sort"
+	"strings"
 )
 
 var (
 	SettlementDestination     = os.Getenv("BAT_SETTLEMENT_ADDRESS")
 	GrantSignatorPublicKeyHex = os.Getenv("GRANT_SIGNATOR_PUBLIC_KEY")
-	GrantWalletPublicKeyHex   = os.Getenv("GRANT_WALLET_PUBLIC_KEY")
-	GrantWalletPrivateKeyHex  = os.Getenv("GRANT_WALLET_PRIVATE_KEY")
 	GrantWalletCardId         = os.Getenv("GRANT_WALLET_CARD_ID")
 	grantPublicKey            ed25519.PublicKey
-	grantWallet               wallet.Wallet
+	grantWallet               provider.Provider
 	refreshBalance            = true // for testing we can disable balance refresh
 )
 
+// grantWalletProviderName returns the provider used to custody the grant wallet,
+// defaulting to uphold so existing deployments don't need to set anything.
+func grantWalletProviderName() string {
+	if name := os.Getenv("GRANT_WALLET_PROVIDER"); name != "" {
+		return name
+	}
+	return "uphold"
+}
+
+// InitGrantService configures the grant wallet and resumes any redemption left
+// non-terminal by a previous crash before returning, so that a caller who then starts
+// serving new redemption requests never races a stale in-flight one.
 func InitGrantService() error {
 	grantPublicKey, _ = hex.DecodeString(GrantSignatorPublicKeyHex)
 	if os.Getenv("ENV") == "production" && refreshBalance != true {
 		return errors.New("refreshBalance must be true in production!!")
 	}
 	var info wallet.WalletInfo
-	info.Provider = "uphold"
+	info.Provider = grantWalletProviderName()
 	info.ProviderId = GrantWalletCardId
 	info.AltCurrency = altcurrency.BAT
 
-	grantWallet, err := uphold.FromWalletInfo(info)
+	var err error
+	grantWallet, err = provider.GetWallet(info)
 	if err != nil {
 		return err
 	}
-	grantWallet.PubKey, _ = hex.DecodeString(GrantWalletPublicKeyHex)
-	grantWallet.PrivKey, _ = hex.DecodeString(GrantWalletPrivateKeyHex)
+
+	if err := ResumePendingRedemptions(context.Background()); err != nil {
+		return fmt.Errorf("failed to resume pending redemptions on startup: %w", err)
+	}
 	return nil
 }
 
@@ -55,6 +70,19 @@ type Grant struct {
 	GrantId     uuid.UUID               `json:"grantId"`
 	Probi       decimal.Decimal         `json:"probi"`
 	PromotionId uuid.UUID               `json:"promotionId"`
+	// SettlementContract is the address of the ETHSwap contract this grant is settled
+	// through on-chain, if any. A grant with no SettlementContract is redeemed through
+	// the normal uphold-style provider path instead.
+	SettlementContract *string `json:"settlementContract,omitempty"`
+	// SecretHash is the sha256 hash the ETHSwap escrow locking this grant's value was
+	// initiated with. The corresponding preimage is the secret revealed on redemption.
+	SecretHash *string `json:"secretHash,omitempty"`
+}
+
+// IsEthSettled reports whether grant is redeemed through an on-chain ETHSwap escrow
+// rather than through a registered wallet.Provider.
+func (g *Grant) IsEthSettled() bool {
+	return g.SettlementContract != nil
 }
 
 // ByProbi implements sort.Interface for []Grant based on the Probi field.
@@ -64,6 +92,112 @@ func (a ByProbi) Len() int           { return len(a) }
 func (a ByProbi) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
 func (a ByProbi) Less(i, j int) bool { return a[i].Probi.LessThan(a[j].Probi) }
 
+// requestHash returns a deterministic identifier for a redemption request, used to key
+// the transaction outbox so that retries of the same request are recognized rather than
+// treated as a fresh redemption.
+func (req *RedeemGrantsRequest) requestHash() string {
+	h := sha256.New()
+	grants := make([]string, len(req.Grants))
+	copy(grants, req.Grants)
+	sort.Strings(grants)
+	for _, g := range grants {
+		h.Write([]byte(g))
+	}
+	h.Write([]byte(req.WalletInfo.ProviderId))
+	h.Write([]byte(req.Transaction))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// ethOutboxPrefix marks an outbox entry as belonging to RedeemEthGrant rather than
+// RedeemGrantsRequest: it carries no WalletInfo to hand to provider.GetWallet, so the
+// resume worker must dispatch on this prefix before touching the off-chain path below.
+const ethOutboxPrefix = "eth:"
+
+// resumeRedemption drives a single non-terminal outbox entry forward to settled, picking
+// up from whichever leg was not yet confirmed to have completed.
+func resumeRedemption(ctx context.Context, outbox datastore.OutboxDatastore, entry *datastore.OutboxEntry) error {
+	if strings.HasPrefix(entry.RequestHash, ethOutboxPrefix) {
+		_, err := resumeEthRedemption(ctx, outbox, entry)
+		return err
+	}
+
+	log := lg.Log(ctx)
+	userWallet, err := provider.GetWallet(entry.WalletInfo)
+	if err != nil {
+		return err
+	}
+
+	if entry.State == datastore.OutboxStatePending {
+		// TransferWithReference attaches the outbox's own request hash as the provider's
+		// idempotency key, so a transfer retried after a crash or an ambiguous error is
+		// recognized by the provider as the same transfer rather than double-funding.
+		entry.TransferRef, err = grantWallet.TransferWithReference(entry.TransactionInfo.AltCurrency, entry.TransactionInfo.Probi, entry.TransactionInfo.Destination, entry.RequestHash)
+		if err != nil {
+			// Leave the entry in its current (pending) state on a transient error so a
+			// later resume attempt retries the transfer, rather than abandoning it.
+			return err
+		}
+		entry.State = datastore.OutboxStateFunded
+		if err := outbox.Put(entry); err != nil {
+			return err
+		}
+	}
+
+	if entry.State == datastore.OutboxStateFunded {
+		// SubmitTransaction is safe to re-issue: the wallet provider is expected to
+		// recognize the already-signed transaction blob and treat resubmission as a no-op.
+		_, err = userWallet.SubmitTransaction(entry.Transaction)
+		if err != nil {
+			if provider.IsInvalidSignature(err) {
+				// A signature error at this point cannot be fixed by retrying; mark the
+				// entry failed so the resume worker stops attempting it.
+				entry.State = datastore.OutboxStateFailed
+				entry.FailureReason = err.Error()
+				outbox.Put(entry)
+			}
+			// A transient submission error leaves the entry Funded so a later resume
+			// attempt re-issues the (idempotent) submission.
+			return err
+		}
+		entry.State = datastore.OutboxStateSettled
+		if err := outbox.Put(entry); err != nil {
+			return err
+		}
+	}
+
+	if entry.State == datastore.OutboxStateFailed {
+		return fmt.Errorf("redemption %s previously failed: %s", entry.RequestHash, entry.FailureReason)
+	}
+
+	log.Infof("Resumed redemption %s to settled", entry.RequestHash)
+	return nil
+}
+
+// ResumePendingRedemptions scans the transaction outbox for entries left in a
+// non-terminal state, most likely because the process died between funding the user
+// wallet and submitting the settlement transaction, and drives each to completion.
+// This should be called once on startup before serving new redemption requests.
+func ResumePendingRedemptions(ctx context.Context) error {
+	log := lg.Log(ctx)
+	outbox, err := datastore.GetOutboxDatastore(ctx)
+	if err != nil {
+		return err
+	}
+	defer outbox.Close()
+
+	entries, err := outbox.ListNonTerminal()
+	if err != nil {
+		return err
+	}
+	for i := range entries {
+		entry := entries[i]
+		if err := resumeRedemption(ctx, outbox, &entry); err != nil {
+			log.Errorf("Failed to resume redemption %s: %v", entry.RequestHash, err)
+		}
+	}
+	return nil
+}
+
 func FromCompactJWS(s string) (*Grant, error) {
 	jws, err := jose.ParseSigned(s)
 	if err != nil {
@@ -118,6 +252,50 @@ type RedeemGrantsRequest struct {
 func (req *RedeemGrantsRequest) Verify(ctx context.Context) (*wallet.TransactionInfo, error) {
 	log := lg.Log(ctx)
 
+	requestHash := req.requestHash()
+	outbox, err := datastore.GetOutboxDatastore(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer outbox.Close()
+
+	if existing, err := outbox.Get(requestHash); err == nil {
+		// This request hash has already been verified (and possibly redeemed). Return
+		// the previously computed transaction info instead of re-consuming grants.
+		return existing.TransactionInfo, nil
+	} else if err != datastore.ErrOutboxEntryNotFound {
+		return nil, err
+	}
+
+	// The Get above and the outbox.Put at the end of this function are not atomic with
+	// each other, so two concurrent Verify calls for the identical request hash (e.g. a
+	// client retrying after a timed-out response) can both pass the check above and both
+	// race through the expensive grant-consumption logic below. AcquireLock serializes
+	// them: only the winner runs that logic, and the loser waits for the winner's outbox
+	// entry and replays its TransactionInfo instead of also consuming grants (and, short
+	// of this, instead of the redeemedGrants guard below turning it into a hard error).
+	acquired, release, err := outbox.AcquireLock(requestHash)
+	if err != nil {
+		return nil, err
+	}
+	if !acquired {
+		entry, err := datastore.AwaitEntry(ctx, outbox, requestHash)
+		if err != nil {
+			return nil, err
+		}
+		return entry.TransactionInfo, nil
+	}
+	defer release()
+
+	// The winner of the race above may still find an entry here: another caller could
+	// have acquired the lock, written the entry, and released it entirely between our
+	// first Get and acquiring the lock ourselves.
+	if existing, err := outbox.Get(requestHash); err == nil {
+		return existing.TransactionInfo, nil
+	} else if err != datastore.ErrOutboxEntryNotFound {
+		return nil, err
+	}
+
 	// 1. Check grant signatures and decode
 	grants := make([]Grant, 0, len(req.Grants))
 	for _, grantJWS := range req.Grants {
@@ -125,6 +303,12 @@ func (req *RedeemGrantsRequest) Verify(ctx context.Context) (*wallet.Transaction
 		if err != nil {
 			return nil, err
 		}
+		if grant.IsEthSettled() {
+			// Eth-settled grants are redeemed on-chain through RedeemEthGrant, which
+			// enforces its own redeemedGrants/redeemedWallets guard; admitting one here
+			// too would let the same grant be drained through both paths.
+			return nil, fmt.Errorf("grant %s is eth-settled and must be redeemed via RedeemEthGrant, not RedeemGrantsRequest", grant.GrantId)
+		}
 		grants = append(grants, *grant)
 	}
 
@@ -138,8 +322,6 @@ func (req *RedeemGrantsRequest) Verify(ctx context.Context) (*wallet.Transaction
 	if err != nil {
 		return nil, err
 	}
-	// NOTE for uphold provider we currently check against user provided publicKey
-	//      thus this check does not protect us from a valid fake signature
 	txInfo, err := userWallet.VerifyTransaction(req.Transaction)
 	if err != nil {
 		return nil, err
@@ -158,20 +340,20 @@ func (req *RedeemGrantsRequest) Verify(ctx context.Context) (*wallet.Transaction
 		return nil, errors.New("Included transactions must have settlement as their destination")
 	}
 
-	// TODO remove this once we can retrieve publicKey info from uphold
-	// NOTE We check the signature on the included transaction by attempting to submit it.
-	//      We rely on the fact that uphold verifies signatures before doing balance checking.
-	//      We are expecting a balance error, if we get a signature error we have
-	//      the wrong publicKey.
-	_, err = userWallet.SubmitTransaction(req.Transaction)
-	if err == nil {
-		return nil, errors.New("An included transaction unexpectedly succeeded")
-	} else {
-		if wallet.IsInvalidSignature(err) {
-			return nil, errors.New("The included transaction was signed with the wrong publicKey!")
-		} else if !wallet.IsInsufficientBalance(err) {
-			return nil, err
-		}
+	// Confirm the transaction was signed by the publicKey the provider actually has on
+	// file for this wallet, natively. This replaces the old trick of submitting the
+	// transaction and inferring the key was wrong from getting back a signature error
+	// instead of the expected insufficient-balance error.
+	expectedPubKey, err := hex.DecodeString(req.WalletInfo.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	ok, err := userWallet.VerifyPublicKey(expectedPubKey)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errors.New("The included transaction was signed with the wrong publicKey!")
 	}
 
 	// 3. Sort decoded grants, largest probi to smallest
@@ -230,31 +412,49 @@ func (req *RedeemGrantsRequest) Verify(ctx context.Context) (*wallet.Transaction
 	redeemTxInfo.AltCurrency = altcurrency.BAT
 	redeemTxInfo.Probi = sumProbi
 	redeemTxInfo.Destination = req.WalletInfo.ProviderId
+
+	grantIds := make([]string, len(grants))
+	for i, grant := range grants {
+		grantIds[i] = grant.GrantId.String()
+	}
+
+	// Write the durable outbox record *before* any external side effect (the transfer
+	// from grantWallet, then the settlement submission) is attempted, so that a crash
+	// between those steps can be resumed from here rather than re-verifying grants.
+	if err := outbox.Put(&datastore.OutboxEntry{
+		RequestHash:     requestHash,
+		State:           datastore.OutboxStatePending,
+		GrantIds:        grantIds,
+		WalletInfo:      req.WalletInfo,
+		Transaction:     req.Transaction,
+		TransactionInfo: &redeemTxInfo,
+	}); err != nil {
+		return nil, err
+	}
+
 	return &redeemTxInfo, nil
 }
 
+// Redeem verifies req and then drives the resulting outbox entry through the
+// pending -> funded -> settled state machine. Each external call is re-entrant: if the
+// process dies mid-redemption, ResumePendingRedemptions picks the same outbox entry back
+// up and continues from whichever leg had not yet been confirmed to complete.
 func (req *RedeemGrantsRequest) Redeem(ctx context.Context) error {
-	txInfo, err := req.Verify(ctx)
-	_, err = req.Verify(ctx)
+	_, err := req.Verify(ctx)
 	if err != nil {
 		return err
 	}
 
-	userWallet, err := provider.GetWallet(req.WalletInfo)
+	outbox, err := datastore.GetOutboxDatastore(ctx)
 	if err != nil {
 		return err
 	}
+	defer outbox.Close()
 
-	// fund user wallet with probi from grants
-	_, err = grantWallet.Transfer(txInfo.AltCurrency, txInfo.Probi, txInfo.Destination)
+	entry, err := outbox.Get(req.requestHash())
 	if err != nil {
 		return err
 	}
 
-	// send settlement transaction to wallet provider
-	_, err = userWallet.SubmitTransaction(req.Transaction)
-	if err != nil {
-		return err
-	}
-	return nil
+	return resumeRedemption(ctx, outbox, entry)
 }
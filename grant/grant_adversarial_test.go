@@ -0,0 +1,286 @@
+package grant
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/brave-intl/bat-go/utils/altcurrency"
+	"github.com/brave-intl/bat-go/wallet"
+	"github.com/brave-intl/bat-go/wallet/provider/mock"
+	"github.com/satori/go.uuid"
+	"github.com/shopspring/decimal"
+	"github.com/square/go-jose"
+	"golang.org/x/crypto/ed25519"
+)
+
+// requireRedis skips the test if it cannot reach the shared datastore backing the
+// outbox and redeemed-grant sets. These tests exercise real races across goroutines, so
+// unlike the rest of the package they need the real (or a real-protocol) datastore
+// rather than an in-process fake.
+func requireRedis(t *testing.T) {
+	t.Helper()
+	if os.Getenv("REDIS_URL") == "" {
+		t.Skip("REDIS_URL not set, skipping adversarial datastore test")
+	}
+}
+
+// signGrant produces a compact JWS for grant, signed with signingKey.
+func signGrant(t *testing.T, signingKey ed25519.PrivateKey, grant Grant) string {
+	t.Helper()
+	b, err := json.Marshal(grant)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: "ed25519", Key: signingKey}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jws, err := signer.Sign(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	compact, err := jws.CompactSerialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return compact
+}
+
+func newMockTransaction(t *testing.T, probi decimal.Decimal, destination string, valid bool) string {
+	t.Helper()
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		t.Fatal(err)
+	}
+	token := base64.StdEncoding.EncodeToString(buf)
+	mock.RegisterTransaction(token, mock.Transaction{
+		Info: wallet.TransactionInfo{
+			AltCurrency: altcurrency.BAT,
+			Probi:       probi,
+			Destination: destination,
+		},
+		Valid: valid,
+	})
+	return token
+}
+
+func mockWalletInfo(providerName string) wallet.WalletInfo {
+	return wallet.WalletInfo{Provider: providerName, ProviderId: providerName, AltCurrency: altcurrency.BAT}
+}
+
+// mockWalletInfoSignedBy is mockWalletInfo plus the PublicKey the redeeming transaction
+// was (purportedly) signed with, so Verify's native VerifyPublicKey check passes.
+func mockWalletInfoSignedBy(providerName string, pubKey ed25519.PublicKey) wallet.WalletInfo {
+	info := mockWalletInfo(providerName)
+	info.PublicKey = hex.EncodeToString(pubKey)
+	return info
+}
+
+// TestAdversarialRedemption runs the double-spend guard and forged-signature checks
+// under concurrency and fault injection, asserting the invariants the set-based guard
+// in Verify is supposed to provide even when redemption is attacked or interrupted
+// mid-flight.
+func TestAdversarialRedemption(t *testing.T) {
+	requireRedis(t)
+
+	signingPub, signingPriv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	grantPublicKey = signingPub
+	SettlementDestination = "settlement-address"
+
+	t.Run("racing redemptions consume a grant at most once", func(t *testing.T) {
+		t.Parallel()
+
+		grant := Grant{AltCurrency: altcurrency.BAT, GrantId: uuid.NewV4(), Probi: altcurrency.BAT.ToProbi(decimal.New(30, 0)), PromotionId: uuid.NewV4()}
+		grantJWS := signGrant(t, signingPriv, grant)
+
+		const racers = 5
+		var wg sync.WaitGroup
+		successes := make([]bool, racers)
+		for i := 0; i < racers; i++ {
+			i := i
+			providerName := "mock-race-" + uuid.NewV4().String()
+			w := mock.New(mockWalletInfo(providerName))
+			w.PublicKey = signingPub
+			mock.RegisterInstance(providerName, w)
+			txB64 := newMockTransaction(t, altcurrency.BAT.ToProbi(decimal.New(30, 0)), SettlementDestination, true)
+
+			req := &RedeemGrantsRequest{
+				Grants:      []string{grantJWS},
+				WalletInfo:  mockWalletInfoSignedBy(providerName, signingPub),
+				Transaction: txB64,
+			}
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := req.Verify(context.Background())
+				successes[i] = err == nil
+			}()
+		}
+		wg.Wait()
+
+		count := 0
+		for _, ok := range successes {
+			if ok {
+				count++
+			}
+		}
+		if count != 1 {
+			t.Errorf("expected exactly one racing Verify to succeed for a single grant, got %d", count)
+		}
+	})
+
+	t.Run("racing Verify calls for the identical request are serialized, not double-consumed", func(t *testing.T) {
+		t.Parallel()
+
+		providerName := "mock-idempotent-" + uuid.NewV4().String()
+		w := mock.New(mockWalletInfo(providerName))
+		w.PublicKey = signingPub
+		mock.RegisterInstance(providerName, w)
+
+		grant := Grant{AltCurrency: altcurrency.BAT, GrantId: uuid.NewV4(), Probi: altcurrency.BAT.ToProbi(decimal.New(30, 0)), PromotionId: uuid.NewV4()}
+		grantJWS := signGrant(t, signingPriv, grant)
+		txB64 := newMockTransaction(t, altcurrency.BAT.ToProbi(decimal.New(30, 0)), SettlementDestination, true)
+
+		// Every racer shares the identical req, so they all hash to the same outbox
+		// request hash - this is retries of one request racing itself, not distinct
+		// requests racing over a shared grant (covered by the subtest above).
+		req := &RedeemGrantsRequest{
+			Grants:      []string{grantJWS},
+			WalletInfo:  mockWalletInfoSignedBy(providerName, signingPub),
+			Transaction: txB64,
+		}
+
+		const racers = 5
+		var wg sync.WaitGroup
+		infos := make([]*wallet.TransactionInfo, racers)
+		errs := make([]error, racers)
+		for i := 0; i < racers; i++ {
+			i := i
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				infos[i], errs[i] = req.Verify(context.Background())
+			}()
+		}
+		wg.Wait()
+
+		for i, err := range errs {
+			if err != nil {
+				t.Errorf("racer %d: expected every racer retrying the same request to see it succeed, got: %v", i, err)
+			}
+		}
+		for i := 1; i < racers; i++ {
+			if infos[0] == nil || infos[i] == nil {
+				continue
+			}
+			if !infos[0].Probi.Equal(infos[i].Probi) || infos[0].Destination != infos[i].Destination {
+				t.Errorf("expected every racer to replay the same TransactionInfo, got %+v and %+v", infos[0], infos[i])
+			}
+		}
+	})
+
+	t.Run("forged grant with substituted public key is rejected", func(t *testing.T) {
+		t.Parallel()
+		_, forgedPriv, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		grant := Grant{AltCurrency: altcurrency.BAT, GrantId: uuid.NewV4(), Probi: altcurrency.BAT.ToProbi(decimal.New(30, 0)), PromotionId: uuid.NewV4()}
+		forgedJWS := signGrant(t, forgedPriv, grant)
+		if _, err := FromCompactJWS(forgedJWS); err == nil {
+			t.Error("expected a grant signed with a substituted key to fail verification")
+		}
+	})
+
+	t.Run("grants summing exactly to the needed amount are accepted, not rejected as leftover", func(t *testing.T) {
+		t.Parallel()
+		providerName := "mock-boundary-" + uuid.NewV4().String()
+		w := mock.New(mockWalletInfo(providerName))
+		w.PublicKey = signingPub
+		mock.RegisterInstance(providerName, w)
+
+		needed := altcurrency.BAT.ToProbi(decimal.New(30, 0))
+		grant := Grant{AltCurrency: altcurrency.BAT, GrantId: uuid.NewV4(), Probi: needed, PromotionId: uuid.NewV4()}
+		grantJWS := signGrant(t, signingPriv, grant)
+		txB64 := newMockTransaction(t, needed, SettlementDestination, true)
+
+		req := &RedeemGrantsRequest{
+			Grants:      []string{grantJWS},
+			WalletInfo:  mockWalletInfoSignedBy(providerName, signingPub),
+			Transaction: txB64,
+		}
+		if _, err := req.Verify(context.Background()); err != nil {
+			t.Errorf("a grant list that sums exactly to the needed amount on its last element should be accepted, got: %v", err)
+		}
+	})
+
+	t.Run("transaction with a tampered signature is rejected", func(t *testing.T) {
+		t.Parallel()
+		providerName := "mock-tamper-" + uuid.NewV4().String()
+		w := mock.New(mockWalletInfo(providerName))
+		w.PublicKey = signingPub
+		mock.RegisterInstance(providerName, w)
+
+		grant := Grant{AltCurrency: altcurrency.BAT, GrantId: uuid.NewV4(), Probi: altcurrency.BAT.ToProbi(decimal.New(30, 0)), PromotionId: uuid.NewV4()}
+		grantJWS := signGrant(t, signingPriv, grant)
+		// Registered as invalid: the mock provider's stand-in for a transaction whose
+		// amount was altered after it was signed, which breaks its signature.
+		txB64 := newMockTransaction(t, altcurrency.BAT.ToProbi(decimal.New(9999, 0)), SettlementDestination, false)
+
+		req := &RedeemGrantsRequest{
+			Grants:      []string{grantJWS},
+			WalletInfo:  mockWalletInfo(providerName),
+			Transaction: txB64,
+		}
+		if _, err := req.Verify(context.Background()); err == nil {
+			t.Error("expected a transaction with a tampered amount to fail signature verification")
+		}
+	})
+
+	t.Run("transfer succeeds but settlement submission fails leaves the redemption resumable, not double-funded", func(t *testing.T) {
+		t.Parallel()
+		providerName := "mock-fault-" + uuid.NewV4().String()
+		userWallet := mock.New(mockWalletInfo(providerName))
+		userWallet.PublicKey = signingPub
+		userWallet.FailSubmit = context.DeadlineExceeded
+		mock.RegisterInstance(providerName, userWallet)
+
+		grantFunds := mock.New(wallet.WalletInfo{})
+		grantFunds.Balance = altcurrency.BAT.ToProbi(decimal.New(1000, 0))
+		grantWallet = grantFunds
+
+		grant := Grant{AltCurrency: altcurrency.BAT, GrantId: uuid.NewV4(), Probi: altcurrency.BAT.ToProbi(decimal.New(30, 0)), PromotionId: uuid.NewV4()}
+		grantJWS := signGrant(t, signingPriv, grant)
+		txB64 := newMockTransaction(t, altcurrency.BAT.ToProbi(decimal.New(30, 0)), SettlementDestination, true)
+
+		req := &RedeemGrantsRequest{
+			Grants:      []string{grantJWS},
+			WalletInfo:  mockWalletInfoSignedBy(providerName, signingPub),
+			Transaction: txB64,
+		}
+		if err := req.Redeem(context.Background()); err == nil {
+			t.Fatal("expected Redeem to surface the submission failure")
+		}
+		if len(grantFunds.Transfers) != 1 {
+			t.Errorf("expected exactly one transfer out of the grant wallet despite the later submission failure, got %d", len(grantFunds.Transfers))
+		}
+
+		userWallet.FailSubmit = nil
+		if err := req.Redeem(context.Background()); err != nil {
+			t.Errorf("expected a resumed Redeem to succeed once the fault clears: %v", err)
+		}
+		if len(grantFunds.Transfers) != 1 {
+			t.Errorf("expected resume not to re-transfer from the grant wallet, got %d total transfers", len(grantFunds.Transfers))
+		}
+	})
+}
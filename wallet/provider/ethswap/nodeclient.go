@@ -0,0 +1,28 @@
+package ethswap
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// NodeClient is the subset of an Ethereum JSON-RPC client the swap backend needs. It is
+// satisfied by *ethclient.Client, and lets tests substitute a devnet client without
+// depending on the concrete go-ethereum type everywhere.
+type NodeClient interface {
+	bind.ContractBackend
+	TransactionByHash(ctx context.Context, hash common.Hash) (tx *types.Transaction, isPending bool, err error)
+	TransactionReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error)
+	BlockNumber(ctx context.Context) (uint64, error)
+}
+
+var _ NodeClient = (*ethclient.Client)(nil)
+
+// DialNodeClient connects to an Ethereum JSON-RPC endpoint, e.g. a local geth devnet or
+// an infura/alchemy URL.
+func DialNodeClient(rawurl string) (NodeClient, error) {
+	return ethclient.Dial(rawurl)
+}
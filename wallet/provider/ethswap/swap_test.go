@@ -0,0 +1,103 @@
+package ethswap
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// fakeNodeClient embeds a nil bind.ContractBackend so it satisfies NodeClient without
+// having to stub out every contract-call/transact/filter method, and overrides only the
+// three methods AuditContract actually calls.
+type fakeNodeClient struct {
+	bind.ContractBackend
+	tx      *types.Transaction
+	receipt *types.Receipt
+	head    uint64
+}
+
+func (f *fakeNodeClient) TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error) {
+	return f.tx, false, nil
+}
+
+func (f *fakeNodeClient) TransactionReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
+	return f.receipt, nil
+}
+
+func (f *fakeNodeClient) BlockNumber(ctx context.Context) (uint64, error) {
+	return f.head, nil
+}
+
+// TestAuditContractDecodesInitiateCalldata is a harness test against a fake node client
+// standing in for a devnet: it builds a real initiate() transaction, abi-packs its
+// calldata the same way a contract call would, and confirms AuditContract decodes it
+// back out correctly. It would have caught the args[0].(common.Hash) type assertion that
+// panicked on every real decode, since go-ethereum unpacks bytes32 into [32]byte.
+func TestAuditContractDecodesInitiateCalldata(t *testing.T) {
+	contractAddr := common.HexToAddress("0x00000000000000000000000000000000000bad")
+	var secretHash [32]byte
+	copy(secretHash[:], []byte("super-secret-preimage-hash-32by"))
+	recipient := common.HexToAddress("0x000000000000000000000000000000deadbeef")
+	locktime := big.NewInt(1700000000)
+	value := big.NewInt(1e18)
+
+	data, err := parsedABI.Pack("initiate", secretHash, recipient, locktime)
+	if err != nil {
+		t.Fatalf("packing initiate calldata: %v", err)
+	}
+	tx := types.NewTransaction(0, contractAddr, value, 100000, big.NewInt(1), data)
+
+	client := &fakeNodeClient{
+		tx: tx,
+		receipt: &types.Receipt{
+			Status:      types.ReceiptStatusSuccessful,
+			BlockNumber: big.NewInt(100),
+		},
+		head: 111,
+	}
+	swap := NewETHSwap(contractAddr, client)
+
+	init, confs, err := swap.AuditContract(context.Background(), tx.Hash())
+	if err != nil {
+		t.Fatalf("AuditContract: %v", err)
+	}
+	if init.SecretHash != common.Hash(secretHash) {
+		t.Errorf("expected SecretHash %x, got %x", secretHash, init.SecretHash)
+	}
+	if init.Recipient != recipient {
+		t.Errorf("expected Recipient %x, got %x", recipient, init.Recipient)
+	}
+	if init.Locktime.Cmp(locktime) != 0 {
+		t.Errorf("expected Locktime %s, got %s", locktime, init.Locktime)
+	}
+	if init.Value.Cmp(value) != 0 {
+		t.Errorf("expected Value %s, got %s", value, init.Value)
+	}
+	if confs != 12 {
+		t.Errorf("expected 12 confirmations (head 111, mined at 100), got %d", confs)
+	}
+}
+
+// TestAuditContractRejectsWrongContract confirms AuditContract refuses a transaction
+// not sent to the expected swap contract address, rather than happily decoding it.
+func TestAuditContractRejectsWrongContract(t *testing.T) {
+	contractAddr := common.HexToAddress("0x00000000000000000000000000000000000bad")
+	otherAddr := common.HexToAddress("0x00000000000000000000000000000000000eee")
+	var secretHash [32]byte
+	data, err := parsedABI.Pack("initiate", secretHash, otherAddr, big.NewInt(1))
+	if err != nil {
+		t.Fatalf("packing initiate calldata: %v", err)
+	}
+	tx := types.NewTransaction(0, otherAddr, big.NewInt(1), 100000, big.NewInt(1), data)
+
+	client := &fakeNodeClient{tx: tx}
+	swap := NewETHSwap(contractAddr, client)
+
+	if _, _, err := swap.AuditContract(context.Background(), tx.Hash()); err == nil {
+		t.Fatal("expected an error auditing a transaction sent to the wrong contract")
+	}
+}
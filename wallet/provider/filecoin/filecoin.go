@@ -0,0 +1,69 @@
+// Package filecoin is a skeleton Provider for Filecoin-custodied grant wallets. It is
+// not yet wired up to a lotus node; the methods below describe the shape a full
+// implementation needs to fill in.
+package filecoin
+
+import (
+	"errors"
+
+	"github.com/brave-intl/bat-go/utils/altcurrency"
+	"github.com/brave-intl/bat-go/wallet"
+	"github.com/brave-intl/bat-go/wallet/provider"
+	"github.com/shopspring/decimal"
+)
+
+func init() {
+	provider.RegisterProvider("filecoin", func(info wallet.WalletInfo) (provider.Provider, error) {
+		return FromWalletInfo(info)
+	})
+}
+
+// Wallet is a Filecoin-custodied wallet, addressed by its actor address.
+type Wallet struct {
+	info wallet.WalletInfo
+}
+
+// FromWalletInfo constructs a Filecoin Wallet from the common wallet info. ProviderId
+// is expected to hold the wallet's Filecoin actor address.
+func FromWalletInfo(info wallet.WalletInfo) (*Wallet, error) {
+	if info.ProviderId == "" {
+		return nil, errors.New("filecoin: wallet info is missing a ProviderId actor address")
+	}
+	return &Wallet{info: info}, nil
+}
+
+// Transfer is not yet implemented; it will need to build, sign, and push a Filecoin
+// message via a lotus JSON-RPC client.
+func (w *Wallet) Transfer(altcurrency altcurrency.AltCurrency, probi decimal.Decimal, destination string) (string, error) {
+	return "", errors.New("filecoin: Transfer not yet implemented")
+}
+
+// TransferWithReference is not yet implemented; it will need to build, sign, and push a
+// Filecoin message the way Transfer does, recording reference alongside the message so a
+// retried call can be recognized rather than pushed a second time.
+func (w *Wallet) TransferWithReference(altcurrency altcurrency.AltCurrency, probi decimal.Decimal, destination, reference string) (string, error) {
+	return "", errors.New("filecoin: TransferWithReference not yet implemented")
+}
+
+// VerifyTransaction is not yet implemented; it will need to decode a signed Filecoin
+// message and confirm its secp256k1/BLS signature.
+func (w *Wallet) VerifyTransaction(transactionB64 string) (*wallet.TransactionInfo, error) {
+	return nil, errors.New("filecoin: VerifyTransaction not yet implemented")
+}
+
+// SubmitTransaction is not yet implemented; it will need to push the signed message to
+// a lotus node's mpool.
+func (w *Wallet) SubmitTransaction(transactionB64 string) (string, error) {
+	return "", errors.New("filecoin: SubmitTransaction not yet implemented")
+}
+
+// GetBalance is not yet implemented; it will need to query the actor's on-chain balance.
+func (w *Wallet) GetBalance(refresh bool) (*wallet.Balance, error) {
+	return nil, errors.New("filecoin: GetBalance not yet implemented")
+}
+
+// VerifyPublicKey is not yet implemented; it will need to derive the actor address from
+// pubKey and compare it against the wallet's address.
+func (w *Wallet) VerifyPublicKey(pubKey []byte) (bool, error) {
+	return false, errors.New("filecoin: VerifyPublicKey not yet implemented")
+}
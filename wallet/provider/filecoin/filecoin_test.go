@@ -0,0 +1,22 @@
+package filecoin
+
+import (
+	"testing"
+
+	"github.com/brave-intl/bat-go/wallet"
+)
+
+// TestFilecoinConformsToProvider is intentionally not wired up yet: every method but
+// FromWalletInfo on this provider returns "not yet implemented", so running the shared
+// conformance suite against it would only ever fail on setup, not exercise anything real.
+// This test exists so the gap is visible (grep for t.Skip, not absence of a file) rather
+// than silently missing the way it was before chunk0-2's review pass. Un-skip it once
+// Transfer/VerifyTransaction/SubmitTransaction/GetBalance/VerifyPublicKey land against a
+// real (or recorded) lotus node.
+func TestFilecoinConformsToProvider(t *testing.T) {
+	t.Skip("filecoin provider is a stub (see filecoin.go); wire up providertest.RunConformanceSuite once it has a real backend")
+
+	if _, err := FromWalletInfo(wallet.WalletInfo{ProviderId: "f01234"}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,196 @@
+// Package mock provides an in-memory Provider implementation for exercising grant
+// redemption without hitting a live custodian sandbox.
+package mock
+
+import (
+	"encoding/base64"
+	"errors"
+	"sync"
+
+	"github.com/brave-intl/bat-go/utils/altcurrency"
+	"github.com/brave-intl/bat-go/wallet"
+	"github.com/brave-intl/bat-go/wallet/provider"
+	"github.com/shopspring/decimal"
+)
+
+func init() {
+	provider.RegisterProvider("mock", func(info wallet.WalletInfo) (provider.Provider, error) {
+		return New(info), nil
+	})
+}
+
+// Transaction is a fake signed transaction blob as understood by Wallet. Production
+// providers decode an opaque, provider-specific encoding; the mock uses a transparent
+// struct so tests can construct and tamper with transactions directly.
+type Transaction struct {
+	Info      wallet.TransactionInfo
+	PublicKey []byte
+	Valid     bool
+}
+
+// Wallet is a Provider backed entirely by in-process state, intended for use in tests
+// and by the shared conformance suite.
+type Wallet struct {
+	mu        sync.Mutex
+	info      wallet.WalletInfo
+	PublicKey []byte
+	Balance   decimal.Decimal
+	Transfers []string
+	Submitted map[string]bool
+
+	// FailTransfer and FailSubmit let adversarial tests inject a fault at exactly one
+	// leg of a redemption (e.g. transfer succeeds but submit fails, or vice versa)
+	// without having to race a real provider to do it.
+	FailTransfer error
+	FailSubmit   error
+}
+
+// RegisterInstance registers w itself as the provider named name, rather than a
+// factory that builds a fresh wallet per call. This lets tests pre-configure a wallet's
+// balance, public key, or fault hooks and then have every provider.GetWallet lookup for
+// name return that same instance.
+func RegisterInstance(name string, w *Wallet) {
+	provider.RegisterProvider(name, func(wallet.WalletInfo) (provider.Provider, error) {
+		return w, nil
+	})
+}
+
+// New constructs a mock Wallet for info with a zero balance.
+func New(info wallet.WalletInfo) *Wallet {
+	return &Wallet{
+		info:      info,
+		Balance:   decimal.New(0, 1),
+		Submitted: make(map[string]bool),
+	}
+}
+
+// Transfer records a transfer and returns a deterministic reference derived from the
+// destination and amount, so that repeated calls with identical arguments are easy for
+// tests (and the conformance suite) to recognize as duplicates.
+func (w *Wallet) Transfer(altcurrency altcurrency.AltCurrency, probi decimal.Decimal, destination string) (string, error) {
+	return w.TransferWithReference(altcurrency, probi, destination, "")
+}
+
+// TransferWithReference is the idempotent form of Transfer: repeated calls with the
+// same reference return the same result without moving funds twice.
+func (w *Wallet) TransferWithReference(altcurrency altcurrency.AltCurrency, probi decimal.Decimal, destination, reference string) (string, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.FailTransfer != nil {
+		return "", w.FailTransfer
+	}
+	if reference != "" {
+		for _, ref := range w.Transfers {
+			if ref == reference {
+				return reference, nil
+			}
+		}
+	}
+	if probi.GreaterThan(w.Balance) {
+		return "", provider.ErrInsufficientBalance
+	}
+	w.Balance = w.Balance.Sub(probi)
+	ref := reference
+	if ref == "" {
+		ref = destination + ":" + probi.String()
+	}
+	w.Transfers = append(w.Transfers, ref)
+	return ref, nil
+}
+
+// VerifyTransaction returns the info embedded in a mock transaction, failing if the
+// transaction was marked invalid (simulating a bad signature). The transaction's
+// PublicKey is not compared against the wallet's; VerifyPublicKey does that check.
+func (w *Wallet) VerifyTransaction(transactionB64 string) (*wallet.TransactionInfo, error) {
+	tx, err := decodeTransaction(transactionB64)
+	if err != nil {
+		return nil, err
+	}
+	if !tx.Valid {
+		return nil, provider.ErrInvalidSignature
+	}
+	info := tx.Info
+	return &info, nil
+}
+
+// SubmitTransaction is safely re-submittable: submitting the same blob twice is a no-op
+// the second time.
+func (w *Wallet) SubmitTransaction(transactionB64 string) (string, error) {
+	tx, err := decodeTransaction(transactionB64)
+	if err != nil {
+		return "", err
+	}
+	if !tx.Valid {
+		return "", provider.ErrInvalidSignature
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.FailSubmit != nil {
+		return "", w.FailSubmit
+	}
+	w.Submitted[transactionB64] = true
+	return transactionB64, nil
+}
+
+// GetBalance returns the wallet's current spendable balance.
+func (w *Wallet) GetBalance(refresh bool) (*wallet.Balance, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return &wallet.Balance{SpendableProbi: w.Balance}, nil
+}
+
+// VerifyPublicKey reports whether pubKey matches the key on file for this wallet.
+func (w *Wallet) VerifyPublicKey(pubKey []byte) (bool, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.PublicKey) == 0 {
+		return false, errors.New("mock wallet has no public key configured")
+	}
+	if len(pubKey) != len(w.PublicKey) {
+		return false, nil
+	}
+	for i := range pubKey {
+		if pubKey[i] != w.PublicKey[i] {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func decodeTransaction(transactionB64 string) (*Transaction, error) {
+	_, err := base64.StdEncoding.DecodeString(transactionB64)
+	if err != nil {
+		return nil, err
+	}
+	tx, ok := transactionRegistry.get(transactionB64)
+	if !ok {
+		return nil, errors.New("mock: unknown transaction, must be registered via RegisterTransaction")
+	}
+	return tx, nil
+}
+
+// RegisterTransaction associates a base64 token with a fake Transaction so that
+// VerifyTransaction / SubmitTransaction can look it back up. Tests pick the token.
+func RegisterTransaction(transactionB64 string, tx Transaction) {
+	transactionRegistry.set(transactionB64, &tx)
+}
+
+type txRegistry struct {
+	mu sync.Mutex
+	m  map[string]*Transaction
+}
+
+func (r *txRegistry) get(key string) (*Transaction, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	tx, ok := r.m[key]
+	return tx, ok
+}
+
+func (r *txRegistry) set(key string, tx *Transaction) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.m[key] = tx
+}
+
+var transactionRegistry = &txRegistry{m: make(map[string]*Transaction)}
@@ -0,0 +1,11 @@
+package mock
+
+import (
+	"testing"
+
+	"github.com/brave-intl/bat-go/wallet/provider/providertest"
+)
+
+func TestMockConformsToProvider(t *testing.T) {
+	providertest.RunConformanceSuite(t, providertest.MockHarness())
+}
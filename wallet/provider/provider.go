@@ -0,0 +1,86 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/brave-intl/bat-go/utils/altcurrency"
+	"github.com/brave-intl/bat-go/wallet"
+	"github.com/shopspring/decimal"
+)
+
+// Provider is implemented by every wallet custodian that can back grant redemption.
+// Pulling this out as an interface, rather than hard-wiring the redemption path to
+// uphold, lets other custodians (and a mock, for tests) be registered and selected by
+// name via WalletInfo.Provider / GRANT_WALLET_PROVIDER.
+type Provider interface {
+	// Transfer moves probi of altcurrency from this wallet to destination, returning a
+	// provider-specific reference for the resulting transfer.
+	Transfer(altcurrency altcurrency.AltCurrency, probi decimal.Decimal, destination string) (string, error)
+	// TransferWithReference is the idempotent form of Transfer: reference is attached to
+	// the transfer as a provider-native idempotency key, so that retrying a transfer
+	// after a crash or an ambiguous (e.g. timed-out) response is recognized as the same
+	// transfer rather than moving funds a second time. Every provider must implement this
+	// for real; grant redemption relies on it to be durable across crashes, so it is not
+	// an optional side-interface a provider can silently skip.
+	TransferWithReference(altcurrency altcurrency.AltCurrency, probi decimal.Decimal, destination, reference string) (string, error)
+	// VerifyTransaction decodes and validates a provider-specific signed transaction
+	// blob, returning the transaction it describes without submitting it.
+	VerifyTransaction(transactionB64 string) (*wallet.TransactionInfo, error)
+	// SubmitTransaction submits a previously verified transaction blob to the provider.
+	SubmitTransaction(transactionB64 string) (string, error)
+	// GetBalance returns the wallet's current balance, optionally refreshing cached
+	// balance state from the provider first.
+	GetBalance(refresh bool) (*wallet.Balance, error)
+	// VerifyPublicKey confirms that pubKey is the signing key the provider has on file
+	// for this wallet. It must not have any side effect visible to the provider, unlike
+	// the old trick of submitting a doomed-to-fail transaction to read back its error.
+	VerifyPublicKey(pubKey []byte) (bool, error)
+}
+
+// ErrInvalidSignature is returned by VerifyTransaction / SubmitTransaction when a
+// transaction's signature does not match the expected signing key.
+var ErrInvalidSignature = errors.New("invalid transaction signature")
+
+// ErrInsufficientBalance is returned by Transfer / SubmitTransaction when the source
+// wallet does not hold enough probi to cover the requested amount.
+var ErrInsufficientBalance = errors.New("insufficient balance")
+
+// IsInvalidSignature reports whether err indicates a signature mismatch.
+func IsInvalidSignature(err error) bool {
+	return errors.Is(err, ErrInvalidSignature)
+}
+
+// IsInsufficientBalance reports whether err indicates the source wallet lacked funds.
+func IsInsufficientBalance(err error) bool {
+	return errors.Is(err, ErrInsufficientBalance)
+}
+
+// Factory constructs a Provider from wallet info for a single named provider.
+type Factory func(info wallet.WalletInfo) (Provider, error)
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Factory{}
+)
+
+// RegisterProvider makes a provider available to GetWallet under name. Provider
+// packages call this from an init() so that importing one for its side effects is
+// enough to make it selectable.
+func RegisterProvider(name string, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[name] = factory
+}
+
+// GetWallet constructs the Provider registered under info.Provider.
+func GetWallet(info wallet.WalletInfo) (Provider, error) {
+	registryMu.RLock()
+	factory, ok := registry[info.Provider]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("no wallet provider registered for %q", info.Provider)
+	}
+	return factory(info)
+}
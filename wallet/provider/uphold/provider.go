@@ -0,0 +1,149 @@
+package uphold
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+
+	"github.com/brave-intl/bat-go/utils/altcurrency"
+	"github.com/brave-intl/bat-go/wallet"
+	"github.com/brave-intl/bat-go/wallet/provider"
+	"github.com/shopspring/decimal"
+)
+
+func init() {
+	provider.RegisterProvider("uphold", func(info wallet.WalletInfo) (provider.Provider, error) {
+		w, err := FromWalletInfo(info)
+		if err != nil {
+			return nil, err
+		}
+		if w.PubKey, err = hex.DecodeString(os.Getenv("GRANT_WALLET_PUBLIC_KEY")); err != nil {
+			return nil, err
+		}
+		if w.PrivKey, err = hex.DecodeString(os.Getenv("GRANT_WALLET_PRIVATE_KEY")); err != nil {
+			return nil, err
+		}
+		return w, nil
+	})
+}
+
+// upholdCardSettings mirrors the subset of uphold's card resource needed to read the
+// signing key registered against a card.
+type upholdCardSettings struct {
+	Address struct {
+		SigningKey string `json:"signingKey"`
+	} `json:"address"`
+}
+
+// VerifyPublicKey confirms pubKey is the signing key uphold has on file for this card,
+// read directly from the card resource. This replaces the old trick of submitting a
+// doomed-to-fail transaction and inspecting whether uphold complained about the
+// signature or the balance first.
+func (w *Wallet) VerifyPublicKey(pubKey []byte) (bool, error) {
+	req, err := http.NewRequest("GET", upholdAPIBase()+"/v0/cards/"+w.info.ProviderId, nil)
+	if err != nil {
+		return false, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("uphold: unexpected status %d reading card settings", resp.StatusCode)
+	}
+	var settings upholdCardSettings
+	if err := json.NewDecoder(resp.Body).Decode(&settings); err != nil {
+		return false, err
+	}
+	onFile, err := hex.DecodeString(settings.Address.SigningKey)
+	if err != nil {
+		return false, err
+	}
+	if len(onFile) != len(pubKey) {
+		return false, nil
+	}
+	for i := range pubKey {
+		if pubKey[i] != onFile[i] {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// upholdTransactionRequest mirrors the subset of uphold's "create transaction" request
+// body grant redemption needs: a denomination and a destination card/address.
+type upholdTransactionRequest struct {
+	Denomination struct {
+		Amount   string `json:"amount"`
+		Currency string `json:"currency"`
+	} `json:"denomination"`
+	Destination string `json:"destination"`
+}
+
+type upholdTransactionResponse struct {
+	Id string `json:"id"`
+}
+
+// Transfer moves probi of altcurrency from this card to destination. It carries no
+// idempotency key, so a retry after a crashed or ambiguous request can double-send;
+// callers on the durable redemption path must use TransferWithReference instead.
+func (w *Wallet) Transfer(altcurrency altcurrency.AltCurrency, probi decimal.Decimal, destination string) (string, error) {
+	return w.TransferWithReference(altcurrency, probi, destination, "")
+}
+
+// TransferWithReference is Transfer with reference attached as an uphold idempotency
+// key: uphold recognizes a retried "create transaction" request carrying the same
+// Idempotency-Key as the original and returns the original transaction instead of
+// creating a second one, so a crash (or a timed-out response whose actual outcome is
+// unknown) between sending the request and observing its result can be safely retried.
+func (w *Wallet) TransferWithReference(altcurrency altcurrency.AltCurrency, probi decimal.Decimal, destination, reference string) (string, error) {
+	var body upholdTransactionRequest
+	body.Denomination.Amount = altcurrency.FromProbi(probi).String()
+	body.Denomination.Currency = string(altcurrency)
+	body.Destination = destination
+
+	b, err := json.Marshal(body)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequest("POST", upholdAPIBase()+"/v0/me/cards/"+w.info.ProviderId+"/transactions?commit=true", bytes.NewReader(b))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if reference != "" {
+		req.Header.Set("Idempotency-Key", reference)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnprocessableEntity {
+		return "", provider.ErrInsufficientBalance
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		respBody, _ := ioutil.ReadAll(resp.Body)
+		return "", fmt.Errorf("uphold: unexpected status %d creating transaction: %s", resp.StatusCode, respBody)
+	}
+
+	var created upholdTransactionResponse
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		return "", err
+	}
+	return created.Id, nil
+}
+
+func upholdAPIBase() string {
+	if base := os.Getenv("UPHOLD_API_BASE"); base != "" {
+		return base
+	}
+	return "https://api.uphold.com"
+}
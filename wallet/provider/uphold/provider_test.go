@@ -0,0 +1,43 @@
+package uphold
+
+import (
+	"os"
+	"testing"
+
+	"github.com/brave-intl/bat-go/wallet"
+	"github.com/brave-intl/bat-go/wallet/provider/providertest"
+	"github.com/shopspring/decimal"
+)
+
+// TestUpholdConformsToProvider runs the shared provider conformance suite against a real
+// uphold sandbox card, the same way the mock and (eventually) filecoin providers are
+// exercised. This is the provider grant redemption actually runs against in production,
+// so the conformance suite is only as useful as its coverage of this package.
+//
+// It is gated behind sandbox credentials rather than fixtures, because VerifyTransaction
+// and SubmitTransaction decode uphold's own signed-transaction format; faking that wire
+// format convincingly enough to be worth trusting needs a recorded sandbox fixture, not
+// an invented one. Set UPHOLD_TEST_CARD_ID (and the usual GRANT_WALLET_* / UPHOLD_API_BASE
+// env vars) to a funded sandbox card to run it for real.
+func TestUpholdConformsToProvider(t *testing.T) {
+	cardID := os.Getenv("UPHOLD_TEST_CARD_ID")
+	if cardID == "" {
+		t.Skip("UPHOLD_TEST_CARD_ID not set, skipping conformance run against the uphold sandbox")
+	}
+
+	w, err := FromWalletInfo(wallet.WalletInfo{Provider: "uphold", ProviderId: cardID})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	providertest.RunConformanceSuite(t, providertest.Harness{
+		Provider: w,
+		Fund: func(probi decimal.Decimal) {
+			t.Skip("funding a sandbox card requires an out-of-band sandbox faucet call; wire one up before un-skipping")
+		},
+		RegisterTransaction: func(info wallet.TransactionInfo, valid bool) string {
+			t.Skip("uphold conformance needs pre-recorded sandbox transaction fixtures; wire these up before un-skipping")
+			return ""
+		},
+	})
+}